@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/drive/v3"
+)
+
+// AuthProvider produces an OAuth2 token source for the Drive API. Each
+// -auth mode below implements it, so NewDriveClient doesn't need to know
+// how the token it's using was obtained.
+type AuthProvider interface {
+	TokenSource(ctx context.Context) (oauth2.TokenSource, error)
+}
+
+// NewAuthProvider selects an AuthProvider by -auth mode: "token" (the
+// existing stored OAuth token from GOOGLE-DOCS_JWT_KEY), "service-account"
+// (a service-account JSON key at credentialsPath, optionally impersonating
+// subject via domain-wide delegation), or "oauth" (an interactive loopback
+// flow using credentialsPath as the OAuth client secret file).
+func NewAuthProvider(mode, credentialsPath, subject string) (AuthProvider, error) {
+	switch mode {
+	case "", "token":
+		return TokenAuthProvider{}, nil
+	case "service-account":
+		if credentialsPath == "" {
+			return nil, fmt.Errorf("-auth service-account requires -credentials <path>")
+		}
+		return ServiceAccountAuthProvider{CredentialsPath: credentialsPath, Subject: subject}, nil
+	case "oauth":
+		if credentialsPath == "" {
+			return nil, fmt.Errorf("-auth oauth requires -credentials <path>")
+		}
+		return OAuthLoopbackAuthProvider{CredentialsPath: credentialsPath}, nil
+	default:
+		return nil, fmt.Errorf("unsupported -auth mode %q (want token, service-account, or oauth)", mode)
+	}
+}
+
+// TokenAuthProvider is the default auth mode: client credentials and a
+// stored OAuth token loaded from the GOOGLE-DOCS_JWT_KEY environment
+// variable, the same source LoadGoogleDocsToken has always used.
+type TokenAuthProvider struct{}
+
+func (TokenAuthProvider) TokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	token, err := LoadGoogleDocsToken()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &oauth2.Config{
+		ClientID:     token.ClientID,
+		ClientSecret: token.ClientSecret,
+		Endpoint:     google.Endpoint,
+		Scopes:       token.Scopes,
+	}
+
+	oauthToken := &oauth2.Token{
+		AccessToken:  token.Token,
+		RefreshToken: token.RefreshToken,
+		TokenType:    "Bearer",
+	}
+
+	return config.TokenSource(ctx, oauthToken), nil
+}
+
+// ServiceAccountAuthProvider authenticates with a service-account JSON
+// key. Setting Subject impersonates that user via domain-wide delegation,
+// letting a G Suite admin export documents on another user's behalf.
+type ServiceAccountAuthProvider struct {
+	CredentialsPath string
+	Subject         string
+}
+
+func (provider ServiceAccountAuthProvider) TokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	keyData, err := os.ReadFile(provider.CredentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account key %q: %w", provider.CredentialsPath, err)
+	}
+
+	jwtConfig, err := google.JWTConfigFromJSON(keyData, drive.DriveScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service account key: %w", err)
+	}
+
+	if provider.Subject != "" {
+		jwtConfig.Subject = provider.Subject
+	}
+
+	return jwtConfig.TokenSource(ctx), nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// bulkExportItem is one document queued for concurrent export, tagged with
+// the relative folder path it should land in (used by -mirror; empty for
+// a flat "all" export) and the format chosen for it.
+type bulkExportItem struct {
+	Document     GoogleDocument
+	RelativePath string
+	Format       ExportFormat
+}
+
+// bulkExportResult is the outcome of exporting one bulkExportItem.
+type bulkExportResult struct {
+	Document   GoogleDocument
+	OutputPath string
+	Bytes      int64
+	Skipped    bool
+	Err        error
+}
+
+// exportConcurrently exports items using parallelism worker goroutines,
+// rendering a per-item progress bar plus an aggregate line, and recording
+// each successful export in manifest. Items the manifest already shows as
+// up to date are skipped. This backs both "all" selections and -mirror
+// traversals.
+func exportConcurrently(client *DriveClient, items []bulkExportItem, outputDirectory string, parallelism int, manifest *Manifest) []bulkExportResult {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	board := newProgressBoard(len(items))
+	results := make([]bulkExportResult, len(items))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < parallelism; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results[index] = exportBulkItem(client, items[index], outputDirectory, index, board, manifest)
+			}
+		}()
+	}
+
+	for index := range items {
+		jobs <- index
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return results
+}
+
+// exportBulkItem exports a single item, reporting progress on board's slot
+// and recording a manifest entry on success.
+func exportBulkItem(client *DriveClient, item bulkExportItem, outputDirectory string, slot int, board *progressBoard, manifest *Manifest) bulkExportResult {
+	localDirectory := outputDirectory
+	if item.RelativePath != "" {
+		localDirectory = filepath.Join(outputDirectory, item.RelativePath)
+	}
+	outputPath := filepath.Join(localDirectory, SanitizeFilename(item.Document.Name)+item.Format.Extension)
+
+	label := item.Document.Name
+
+	if manifest.UpToDate(item.Document.ID, item.Document.ModifiedAt, outputPath) {
+		board.finish(slot, label, "up to date, skipped")
+		return bulkExportResult{Document: item.Document, OutputPath: outputPath, Skipped: true}
+	}
+
+	bytesWritten, err := client.ExportDocument(item.Document.ID, outputPath, item.Format, func(written, total int64) {
+		board.update(slot, label, written, total)
+	})
+	if err != nil {
+		board.finish(slot, label, "failed: "+err.Error())
+		return bulkExportResult{Document: item.Document, OutputPath: outputPath, Err: err}
+	}
+
+	board.finish(slot, label, "done")
+
+	if hash, hashErr := fileSHA256(outputPath); hashErr == nil {
+		manifest.Put(ManifestEntry{
+			ID:         item.Document.ID,
+			Name:       item.Document.Name,
+			ModifiedAt: item.Document.ModifiedAt,
+			Format:     item.Format.Extension,
+			OutputPath: outputPath,
+			SHA256:     hash,
+			Bytes:      bytesWritten,
+		})
+	}
+
+	return bulkExportResult{Document: item.Document, OutputPath: outputPath, Bytes: bytesWritten}
+}
+
+// summarizeBulkResults prints a one-line summary and any per-document
+// errors after a concurrent export run.
+func summarizeBulkResults(results []bulkExportResult) {
+	var exported, skipped, failed int
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+			fmt.Fprintf(os.Stderr, "Error exporting %s: %v\n", result.Document.Name, result.Err)
+		case result.Skipped:
+			skipped++
+		default:
+			exported++
+		}
+	}
+
+	fmt.Printf("\n%d exported, %d skipped (up to date), %d failed\n", exported, skipped, failed)
+}
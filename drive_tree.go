@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// driveFile is the subset of Drive file metadata the tree walker needs;
+// it exists alongside GoogleDocument because folders (which never get
+// exported) don't need the description/createdTime fields GoogleDocument is
+// built around.
+type driveFile struct {
+	ID           string
+	Name         string
+	MimeType     string
+	ModifiedTime string
+}
+
+// dirCache resolves a Drive folder ID to the local directory path it
+// mirrors to, caching every folder visited so each parent ID is only
+// resolved once, the same trick rclone's Drive backend uses to avoid
+// re-walking ancestors for every file it lists.
+type dirCache struct {
+	paths map[string]string
+}
+
+func newDirCache(rootID, rootPath string) *dirCache {
+	return &dirCache{paths: map[string]string{rootID: rootPath}}
+}
+
+func (cache *dirCache) path(folderID string) string {
+	return cache.paths[folderID]
+}
+
+func (cache *dirCache) put(folderID, path string) {
+	cache.paths[folderID] = path
+}
+
+// DriveTreeEntry is a Google-native file discovered while walking a Drive
+// folder tree, tagged with the folder path (relative to the mirror root)
+// it lives under.
+type DriveTreeEntry struct {
+	Document     GoogleDocument
+	RelativePath string
+}
+
+// ListDriveTree walks the folder tree rooted at folderIDOrPath (a Drive
+// folder ID, or a "/"-separated path resolved from "root") and returns
+// every Google-native file found beneath it.
+func (client *DriveClient) ListDriveTree(folderIDOrPath string) ([]DriveTreeEntry, error) {
+	rootID, err := client.ResolveFolderID(folderIDOrPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []DriveTreeEntry
+	if err := client.walkFolder(rootID, newDirCache(rootID, ""), &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (client *DriveClient) walkFolder(folderID string, cache *dirCache, entries *[]DriveTreeEntry) error {
+	relativePath := cache.path(folderID)
+
+	children, err := client.listChildren(folderID)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if child.MimeType == MimeTypeFolder {
+			childPath := child.Name
+			if relativePath != "" {
+				childPath = filepath.Join(relativePath, child.Name)
+			}
+
+			cache.put(child.ID, childPath)
+			if err := client.walkFolder(child.ID, cache, entries); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, ok := ExportFormatsByMimeType[child.MimeType]; !ok {
+			continue
+		}
+
+		*entries = append(*entries, DriveTreeEntry{
+			Document: GoogleDocument{
+				ID:         child.ID,
+				Name:       child.Name,
+				MimeType:   child.MimeType,
+				ModifiedAt: child.ModifiedTime,
+			},
+			RelativePath: relativePath,
+		})
+	}
+
+	return nil
+}
+
+// listChildren returns the immediate children of a Drive folder, paginating
+// through Files.List the same way ListGoogleDocs does.
+func (client *DriveClient) listChildren(folderID string) ([]driveFile, error) {
+	var children []driveFile
+
+	pageToken := ""
+	for {
+		query := client.service.Files.List().
+			Q(fmt.Sprintf("'%s' in parents and trashed=false", escapeDriveQueryValue(folderID))).
+			Fields("nextPageToken, files(id, name, mimeType, modifiedTime)").
+			PageSize(100)
+
+		if pageToken != "" {
+			query = query.PageToken(pageToken)
+		}
+
+		var response *drive.FileList
+		err := client.pacer.call(func() error {
+			var callErr error
+			response, callErr = query.Do()
+			return callErr
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list children of folder %s: %w", folderID, err)
+		}
+
+		for _, file := range response.Files {
+			children = append(children, driveFile{ID: file.Id, Name: file.Name, MimeType: file.MimeType, ModifiedTime: file.ModifiedTime})
+		}
+
+		pageToken = response.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	return children, nil
+}
+
+// ResolveFolderID resolves folderIDOrPath into a Drive folder ID. A value
+// containing "/" is treated as a path of folder names and walked down from
+// "root" one segment at a time; anything else is assumed to already be a
+// folder ID (including the literal "root").
+func (client *DriveClient) ResolveFolderID(folderIDOrPath string) (string, error) {
+	if !strings.Contains(folderIDOrPath, "/") {
+		return folderIDOrPath, nil
+	}
+
+	parentID := "root"
+	for _, segment := range strings.Split(folderIDOrPath, "/") {
+		if segment == "" {
+			continue
+		}
+
+		query := client.service.Files.List().
+			Q(fmt.Sprintf("name='%s' and '%s' in parents and mimeType='%s' and trashed=false",
+				escapeDriveQueryValue(segment), escapeDriveQueryValue(parentID), MimeTypeFolder)).
+			Fields("files(id, name)").
+			PageSize(1)
+
+		var response *drive.FileList
+		err := client.pacer.call(func() error {
+			var callErr error
+			response, callErr = query.Do()
+			return callErr
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve folder %q in path %q: %w", segment, folderIDOrPath, err)
+		}
+		if len(response.Files) == 0 {
+			return "", fmt.Errorf("folder %q not found in path %q", segment, folderIDOrPath)
+		}
+
+		parentID = response.Files[0].Id
+	}
+
+	return parentID, nil
+}
+
+func escapeDriveQueryValue(value string) string {
+	return strings.ReplaceAll(value, "'", "\\'")
+}
+
+// MirrorFolder recreates the folder hierarchy rooted at folderIDOrPath
+// under outputDirectory, exporting every Google-native file it finds
+// concurrently (parallelism workers) using the first export format in
+// preferredExtensions that its source mimeType supports. Results are
+// recorded in outputDirectory/manifest.json so a re-run can skip files
+// whose ID, modifiedTime and on-disk hash haven't changed.
+func (client *DriveClient) MirrorFolder(folderIDOrPath string, outputDirectory string, preferredExtensions []string, parallelism int) error {
+	entries, err := client.ListDriveTree(folderIDOrPath)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := LoadManifest(outputDirectory)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load manifest, starting fresh: %v\n", err)
+		manifest = NewManifest()
+	}
+
+	var items []bulkExportItem
+	for _, entry := range entries {
+		format, ok := PreferredExportFormat(entry.Document.MimeType, preferredExtensions)
+		if !ok {
+			fmt.Printf("Skipping %s: no export format available for %s\n", entry.Document.Name, entry.Document.MimeType)
+			continue
+		}
+
+		items = append(items, bulkExportItem{Document: entry.Document, RelativePath: entry.RelativePath, Format: format})
+	}
+
+	results := exportConcurrently(client, items, outputDirectory, parallelism, manifest)
+
+	if err := manifest.Save(outputDirectory); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save manifest: %v\n", err)
+	}
+
+	summarizeBulkResults(results)
+	return nil
+}
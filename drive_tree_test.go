@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPreferredExportFormat(t *testing.T) {
+	format, ok := PreferredExportFormat(MimeTypeDocument, []string{"md", "pdf"})
+	if !ok {
+		t.Fatal("expected a format for MimeTypeDocument")
+	}
+	if !strings.HasSuffix(format.Extension, "md") {
+		t.Errorf("Extension = %q, want the preferred .md format", format.Extension)
+	}
+
+	format, ok = PreferredExportFormat(MimeTypeSpreadsheet, []string{"docx"})
+	if !ok {
+		t.Fatal("expected a fallback format for MimeTypeSpreadsheet")
+	}
+	if format.Extension != ".xlsx" {
+		t.Errorf("Extension = %q, want the first registered Sheets format .xlsx when none of the preferences match", format.Extension)
+	}
+
+	if _, ok := PreferredExportFormat("application/octet-stream", []string{"pdf"}); ok {
+		t.Error("expected ok=false for a mimeType with no registered export formats")
+	}
+}
+
+func TestDirCache(t *testing.T) {
+	cache := newDirCache("root-id", "")
+
+	if got := cache.path("root-id"); got != "" {
+		t.Errorf("path(root-id) = %q, want empty string for the mirror root", got)
+	}
+
+	cache.put("child-id", "Subfolder")
+	if got := cache.path("child-id"); got != "Subfolder" {
+		t.Errorf("path(child-id) = %q, want %q", got, "Subfolder")
+	}
+
+	if got := cache.path("unknown-id"); got != "" {
+		t.Errorf("path(unknown-id) = %q, want empty string for an unvisited folder", got)
+	}
+}
+
+// TestResolveFolderID drives ResolveFolderID against a fake Drive server
+// that resolves one path segment at a time, the same way Drive's
+// files.list does, to confirm the "/"-path walk picks up each segment's
+// resolved ID as the next segment's parent.
+func TestResolveFolderID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+
+		var id string
+		switch {
+		case strings.Contains(q, "name='Team Docs'") && strings.Contains(q, "'root' in parents"):
+			id = "team-docs-id"
+		case strings.Contains(q, "name='2024'") && strings.Contains(q, "'team-docs-id' in parents"):
+			id = "2024-id"
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"files": []any{}})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"files": []map[string]string{{"id": id, "name": "whatever"}},
+		})
+	}))
+	defer server.Close()
+
+	client := newTestDriveClient(t, server)
+
+	got, err := client.ResolveFolderID("Team Docs/2024")
+	if err != nil {
+		t.Fatalf("ResolveFolderID returned an error: %v", err)
+	}
+	if got != "2024-id" {
+		t.Errorf("ResolveFolderID = %q, want %q", got, "2024-id")
+	}
+}
+
+func TestResolveFolderIDWithoutSlashIsPassedThrough(t *testing.T) {
+	client := &DriveClient{}
+
+	got, err := client.ResolveFolderID("some-existing-folder-id")
+	if err != nil {
+		t.Fatalf("ResolveFolderID returned an error: %v", err)
+	}
+	if got != "some-existing-folder-id" {
+		t.Errorf("ResolveFolderID = %q, want the input passed through unchanged", got)
+	}
+}
+
+func TestResolveFolderIDMissingSegment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"files": []any{}})
+	}))
+	defer server.Close()
+
+	client := newTestDriveClient(t, server)
+
+	if _, err := client.ResolveFolderID("Missing/Folder"); err == nil {
+		t.Error("expected an error when a path segment doesn't resolve to any folder")
+	}
+}
+
+func TestEscapeDriveQueryValue(t *testing.T) {
+	got := escapeDriveQueryValue("O'Brien's Notes")
+	want := "O\\'Brien\\'s Notes"
+	if got != want {
+		t.Errorf("escapeDriveQueryValue = %q, want %q", got, want)
+	}
+}
@@ -4,16 +4,21 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/option"
 )
 
+// exportChunkSize bounds how much of an export is requested per HTTP call.
+// Pulling large exports in chunks means a transient failure partway through
+// only costs a retry of the current chunk, not a restart from byte zero.
+const exportChunkSize = 8 * 1024 * 1024
+
 // ExportFormat defines a supported export format
 type ExportFormat struct {
 	MimeType  string
@@ -33,12 +38,81 @@ var ExportFormats = map[string]ExportFormat{
 	"markdown": {MimeType: "text/markdown", Extension: ".md"},
 }
 
+// Google-native mime types, as returned by the Drive API for files that
+// only exist as Google Docs/Sheets/Slides/Drawings and must be exported
+// rather than downloaded directly.
+const (
+	MimeTypeFolder       = "application/vnd.google-apps.folder"
+	MimeTypeDocument     = "application/vnd.google-apps.document"
+	MimeTypeSpreadsheet  = "application/vnd.google-apps.spreadsheet"
+	MimeTypePresentation = "application/vnd.google-apps.presentation"
+	MimeTypeDrawing      = "application/vnd.google-apps.drawing"
+)
+
+// ExportFormatsByMimeType maps each Google-native source mimeType to the
+// export formats Drive offers for it, in preference order. Unlike
+// ExportFormats (which only covers Docs and is keyed by extension for the
+// -t/-type flag), this is keyed by the source mimeType so callers that
+// walk a mix of Docs, Sheets, Slides and Drawings can pick a format without
+// knowing the file type ahead of time.
+var ExportFormatsByMimeType = map[string][]ExportFormat{
+	MimeTypeDocument: {
+		ExportFormats["pdf"],
+		ExportFormats["docx"],
+		ExportFormats["odt"],
+		ExportFormats["rtf"],
+		ExportFormats["txt"],
+		ExportFormats["html"],
+		ExportFormats["epub"],
+		ExportFormats["md"],
+	},
+	MimeTypeSpreadsheet: {
+		{MimeType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Extension: ".xlsx"},
+		{MimeType: "application/vnd.oasis.opendocument.spreadsheet", Extension: ".ods"},
+		{MimeType: "text/csv", Extension: ".csv"},
+		{MimeType: "application/pdf", Extension: ".pdf"},
+	},
+	MimeTypePresentation: {
+		{MimeType: "application/vnd.openxmlformats-officedocument.presentationml.presentation", Extension: ".pptx"},
+		{MimeType: "application/vnd.oasis.opendocument.presentation", Extension: ".odp"},
+		{MimeType: "application/pdf", Extension: ".pdf"},
+	},
+	MimeTypeDrawing: {
+		{MimeType: "image/svg+xml", Extension: ".svg"},
+		{MimeType: "image/png", Extension: ".png"},
+		{MimeType: "application/pdf", Extension: ".pdf"},
+	},
+}
+
+// PreferredExportFormat returns the export format for sourceMimeType that
+// matches the first extension in preferredExtensions it supports. If none
+// of the preferred extensions apply, it falls back to the first format
+// Drive offers for that mimeType. The second return value is false if
+// sourceMimeType has no registered export formats at all.
+func PreferredExportFormat(sourceMimeType string, preferredExtensions []string) (ExportFormat, bool) {
+	formats, ok := ExportFormatsByMimeType[sourceMimeType]
+	if !ok || len(formats) == 0 {
+		return ExportFormat{}, false
+	}
+
+	for _, preferred := range preferredExtensions {
+		preferred = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(preferred)), ".")
+		for _, format := range formats {
+			if strings.TrimPrefix(format.Extension, ".") == preferred {
+				return format, true
+			}
+		}
+	}
+
+	return formats[0], true
+}
 
 // GoogleDocument represents a Google Doc with its metadata
 type GoogleDocument struct {
 	ID          string
 	Name        string
 	Description string
+	MimeType    string
 	ModifiedAt  string
 	CreatedAt   string
 }
@@ -46,33 +120,25 @@ type GoogleDocument struct {
 // DriveClient wraps Google Drive API operations
 type DriveClient struct {
 	service *drive.Service
+	pacer   *pacer
 }
 
-// NewDriveClient creates a new Drive client from token data
-func NewDriveClient(token *TokenData) (*DriveClient, error) {
+// NewDriveClient creates a new Drive client authenticated via provider,
+// pacing every API call it makes according to pacing.
+func NewDriveClient(provider AuthProvider, pacing PacerConfig) (*DriveClient, error) {
 	ctx := context.Background()
 
-	config := &oauth2.Config{
-		ClientID:     token.ClientID,
-		ClientSecret: token.ClientSecret,
-		Endpoint:     google.Endpoint,
-		Scopes:       token.Scopes,
-	}
-
-	oauthToken := &oauth2.Token{
-		AccessToken:  token.Token,
-		RefreshToken: token.RefreshToken,
-		TokenType:    "Bearer",
+	tokenSource, err := provider.TokenSource(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up authentication: %w", err)
 	}
 
-	client := config.Client(ctx, oauthToken)
-
-	service, err := drive.NewService(ctx, option.WithHTTPClient(client))
+	service, err := drive.NewService(ctx, option.WithTokenSource(tokenSource))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Drive service: %w", err)
 	}
 
-	return &DriveClient{service: service}, nil
+	return &DriveClient{service: service, pacer: newPacer(pacing)}, nil
 }
 
 // ListGoogleDocs returns all Google Docs in the user's Drive
@@ -90,7 +156,12 @@ func (client *DriveClient) ListGoogleDocs() ([]GoogleDocument, error) {
 			query = query.PageToken(pageToken)
 		}
 
-		response, err := query.Do()
+		var response *drive.FileList
+		err := client.pacer.call(func() error {
+			var callErr error
+			response, callErr = query.Do()
+			return callErr
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to list files: %w", err)
 		}
@@ -100,6 +171,7 @@ func (client *DriveClient) ListGoogleDocs() ([]GoogleDocument, error) {
 				ID:          file.Id,
 				Name:        file.Name,
 				Description: file.Description,
+				MimeType:    MimeTypeDocument,
 				ModifiedAt:  file.ModifiedTime,
 				CreatedAt:   file.CreatedTime,
 			})
@@ -114,35 +186,109 @@ func (client *DriveClient) ListGoogleDocs() ([]GoogleDocument, error) {
 	return documents, nil
 }
 
-// ExportDocument exports a Google Doc to the specified format
-func (client *DriveClient) ExportDocument(documentID string, outputPath string, format ExportFormat) error {
-	response, err := client.service.Files.Export(documentID, format.MimeType).Download()
-	if err != nil {
-		return fmt.Errorf("failed to export document: %w", err)
-	}
-	defer response.Body.Close()
-
-	// Ensure output directory exists
+// ExportDocument exports a Google Doc to the specified format and returns
+// the number of bytes written. It pulls the export in exportChunkSize
+// chunks via Range requests on the export media URL, pacing and retrying
+// each chunk independently, so a transient failure partway through a large
+// export resumes from where it left off instead of restarting at byte
+// zero. onProgress, if non-nil, is called after every chunk with the bytes
+// written so far and the total size if known (0 or -1 if the server didn't
+// report one); callers that don't need progress reporting can pass nil.
+func (client *DriveClient) ExportDocument(documentID string, outputPath string, format ExportFormat, onProgress func(written, total int64)) (int64, error) {
 	outputDirectory := filepath.Dir(outputPath)
 	if outputDirectory != "." && outputDirectory != "" {
 		if err := os.MkdirAll(outputDirectory, 0755); err != nil {
-			return fmt.Errorf("failed to create output directory: %w", err)
+			return 0, fmt.Errorf("failed to create output directory: %w", err)
 		}
 	}
 
 	outputFile, err := os.Create(outputPath)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+		return 0, fmt.Errorf("failed to create output file: %w", err)
 	}
 	defer outputFile.Close()
 
-	bytesWritten, err := io.Copy(outputFile, response.Body)
-	if err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	var offset int64
+	for {
+		var response *http.Response
+		err := client.pacer.call(func() error {
+			call := client.service.Files.Export(documentID, format.MimeType)
+			call.Header().Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+exportChunkSize-1))
+
+			var callErr error
+			response, callErr = call.Download()
+			return callErr
+		})
+		if err != nil {
+			return offset, fmt.Errorf("failed to export document: %w", err)
+		}
+
+		total := exportTotalSize(response)
+
+		// Only a 206 confirms the server actually honored our Range
+		// header and is handing us one chunk at a time; anything else
+		// (notably a 200) means it ignored Range and response.Body is the
+		// entire export, so read it in one unbounded copy and stop rather
+		// than looping a bounded read against a body that will never
+		// shrink to fit.
+		ranged := response.StatusCode == http.StatusPartialContent
+
+		var written int64
+		var copyErr error
+		if ranged {
+			written, copyErr = io.CopyN(outputFile, response.Body, exportChunkSize)
+			if copyErr == io.EOF {
+				copyErr = nil
+			}
+		} else {
+			written, copyErr = io.Copy(outputFile, response.Body)
+		}
+		response.Body.Close()
+		offset += written
+
+		if onProgress != nil {
+			onProgress(offset, total)
+		}
+
+		if copyErr != nil {
+			return offset, fmt.Errorf("failed to write file: %w", copyErr)
+		}
+
+		// A non-ranged response always carries the whole export, so
+		// there's nothing left to fetch. A ranged response that came
+		// back short means the export ended before filling a full chunk.
+		// And when total is known and we've already written that many
+		// bytes, stop even if the last chunk landed exactly on a chunk
+		// boundary — otherwise the next iteration would request a range
+		// starting past EOF and Drive would answer with a 416.
+		if !ranged || written < exportChunkSize || (total > 0 && offset >= total) {
+			break
+		}
+	}
+
+	return offset, nil
+}
+
+// exportTotalSize returns the total export size if the server reported
+// one, either via a Content-Range header on a ranged response or via
+// Content-Length on a plain 200 response. If the size is unknown it returns
+// 0, or -1 for a 200 response without Content-Length (ContentLength is
+// passed through as is); callers treat any value <= 0 as "can't render a
+// determinate bar".
+func exportTotalSize(response *http.Response) int64 {
+	if contentRange := response.Header.Get("Content-Range"); contentRange != "" {
+		if slash := strings.LastIndex(contentRange, "/"); slash != -1 {
+			if total, err := strconv.ParseInt(contentRange[slash+1:], 10, 64); err == nil {
+				return total
+			}
+		}
+	}
+
+	if response.StatusCode == http.StatusOK {
+		return response.ContentLength
 	}
 
-	fmt.Printf("Exported %d bytes to %s\n", bytesWritten, outputPath)
-	return nil
+	return 0
 }
 
 // SanitizeFilename removes characters that are invalid in filenames
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// newTestDriveClient builds a DriveClient backed by server instead of the
+// real Drive API, with a fast pacer so retry tests don't sleep for real.
+func newTestDriveClient(t *testing.T, server *httptest.Server) *DriveClient {
+	t.Helper()
+
+	service, err := drive.NewService(context.Background(),
+		option.WithHTTPClient(server.Client()),
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("drive.NewService: %v", err)
+	}
+
+	return &DriveClient{
+		service: service,
+		pacer:   newPacer(PacerConfig{MinSleep: time.Microsecond, MaxSleep: time.Microsecond, Decay: 2}),
+	}
+}
+
+// TestExportDocumentExactChunkMultiple reproduces the trailing-416 bug: a
+// payload that's an exact multiple of exportChunkSize must not cause the
+// loop to issue one more Range request past EOF.
+func TestExportDocumentExactChunkMultiple(t *testing.T) {
+	payload := bytes.Repeat([]byte("a"), 2*exportChunkSize)
+	var requestCount int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+
+		rangeHeader := r.Header.Get("Range")
+		var start int64
+		if _, err := fmt.Sscanf(rangeHeader, "bytes=%d-", &start); err != nil {
+			t.Fatalf("failed to parse Range header %q: %v", rangeHeader, err)
+		}
+
+		if start >= int64(len(payload)) {
+			t.Fatalf("got a request starting past EOF at offset %d (total %d)", start, len(payload))
+		}
+
+		end := start + exportChunkSize
+		if end > int64(len(payload)) {
+			end = int64(len(payload))
+		}
+
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(payload)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(payload[start:end])
+	}))
+	defer server.Close()
+
+	client := newTestDriveClient(t, server)
+
+	outputPath := filepath.Join(t.TempDir(), "doc.pdf")
+	written, err := client.ExportDocument("doc-1", outputPath, ExportFormats["pdf"], nil)
+	if err != nil {
+		t.Fatalf("ExportDocument returned an error: %v", err)
+	}
+
+	if written != int64(len(payload)) {
+		t.Errorf("written = %d, want %d", written, len(payload))
+	}
+	if requestCount != 2 {
+		t.Errorf("requestCount = %d, want 2 (no trailing request past EOF)", requestCount)
+	}
+
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Error("output file contents don't match the exported payload")
+	}
+}
+
+// TestExportDocumentIgnoresRangeAndReturns200 covers a server that ignores
+// our Range header entirely and returns the whole export with a 200; the
+// loop must take the unbounded fallback path rather than looping forever
+// re-copying the same full body.
+func TestExportDocumentIgnoresRangeAndReturns200(t *testing.T) {
+	payload := bytes.Repeat([]byte("b"), exportChunkSize+1024)
+	var requestCount int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.WriteHeader(http.StatusOK)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	client := newTestDriveClient(t, server)
+
+	outputPath := filepath.Join(t.TempDir(), "doc.pdf")
+	written, err := client.ExportDocument("doc-1", outputPath, ExportFormats["pdf"], nil)
+	if err != nil {
+		t.Fatalf("ExportDocument returned an error: %v", err)
+	}
+
+	if written != int64(len(payload)) {
+		t.Errorf("written = %d, want %d", written, len(payload))
+	}
+	if requestCount != 1 {
+		t.Errorf("requestCount = %d, want 1 for a non-ranged response", requestCount)
+	}
+}
+
+func TestExportTotalSize(t *testing.T) {
+	rangedResponse := &http.Response{
+		StatusCode: http.StatusPartialContent,
+		Header:     http.Header{"Content-Range": []string{"bytes 0-8388607/16777216"}},
+	}
+	if got := exportTotalSize(rangedResponse); got != 16777216 {
+		t.Errorf("exportTotalSize(ranged) = %d, want 16777216", got)
+	}
+
+	plainResponse := &http.Response{StatusCode: http.StatusOK, ContentLength: 4096}
+	if got := exportTotalSize(plainResponse); got != 4096 {
+		t.Errorf("exportTotalSize(200) = %d, want 4096", got)
+	}
+
+	unknownResponse := &http.Response{StatusCode: http.StatusOK, ContentLength: -1}
+	if got := exportTotalSize(unknownResponse); got != -1 {
+		t.Errorf("exportTotalSize(unknown) = %d, want -1 (ContentLength passthrough)", got)
+	}
+}
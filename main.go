@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
@@ -15,12 +16,21 @@ func main() {
 	listOnly := flag.Bool("list", false, "List all documents without filtering")
 	exportType := flag.String("t", "pdf", "Export format: pdf, docx, odt, rtf, txt, html, epub, md")
 	flag.StringVar(exportType, "type", "pdf", "Export format: pdf, docx, odt, rtf, txt, html, epub, md")
+	mirrorTarget := flag.String("mirror", "", "Mirror a Drive folder (ID or \"/\"-separated path) into -output, recreating its folder structure")
+	preferredExtensions := flag.String("ext", "pdf,docx,md", "Comma-separated preferred export extensions for -mirror, tried in order per file type")
+	jsonOutput := flag.Bool("json", false, "Export all matches and emit the result as indented JSON on stdout, instead of prompting interactively")
+	minSleep := flag.Duration("min-sleep", DefaultPacerConfig.MinSleep, "Minimum backoff delay before retrying a rate-limited Drive API call")
+	maxSleep := flag.Duration("max-sleep", DefaultPacerConfig.MaxSleep, "Maximum backoff delay between retries of a rate-limited Drive API call")
+	parallel := flag.Int("parallel", 1, "Number of documents to export concurrently for 'all' selections and -mirror")
+	authMode := flag.String("auth", "token", "Authentication mode: token, service-account, or oauth")
+	credentialsPath := flag.String("credentials", "", "Path to a service-account JSON key (-auth service-account) or OAuth client secret JSON (-auth oauth)")
+	subject := flag.String("subject", "", "G Suite user to impersonate via domain-wide delegation (-auth service-account only)")
 	flag.Parse()
 
 	// Get the query from remaining args
 	query := strings.Join(flag.Args(), " ")
 
-	if query == "" && !*listOnly {
+	if query == "" && !*listOnly && *mirrorTarget == "" {
 		fmt.Println("gdoc2doc - Export Google Docs using natural language queries")
 		fmt.Println()
 		fmt.Println("Usage:")
@@ -31,15 +41,26 @@ func main() {
 		fmt.Println("  -t, -type <fmt> Export format (default: pdf)")
 		fmt.Println("                  Formats: pdf, docx, odt, rtf, txt, html, epub, md")
 		fmt.Println("  -list           List all documents without filtering")
+		fmt.Println("  -mirror <id>    Mirror a Drive folder (ID or path) into -output")
+		fmt.Println("  -ext <list>     Preferred export extensions for -mirror (default: pdf,docx,md)")
+		fmt.Println("  -json           Export all matches and print the result as JSON on stdout")
+		fmt.Println("  -min-sleep <d>  Minimum backoff delay on rate-limited Drive calls (default: 10ms)")
+		fmt.Println("  -max-sleep <d>  Maximum backoff delay on rate-limited Drive calls (default: 2s)")
+		fmt.Println("  -parallel <n>   Concurrent exports for 'all' and -mirror (default: 1)")
+		fmt.Println("  -auth <mode>    Authentication mode: token, service-account, or oauth (default: token)")
+		fmt.Println("  -credentials <path> Service-account key or OAuth client secret for -auth")
+		fmt.Println("  -subject <email>    User to impersonate via domain-wide delegation (service-account)")
 		fmt.Println()
 		fmt.Println("Examples:")
 		fmt.Println("  gdoc2doc \"meeting notes\"")
 		fmt.Println("  gdoc2doc -t md \"project proposal\"")
 		fmt.Println("  gdoc2doc -type docx -output ./exports \"report\"")
 		fmt.Println("  gdoc2doc -list")
+		fmt.Println("  gdoc2doc -mirror \"Team Docs/2024\" -output ./mirror -ext docx,md,pdf")
+		fmt.Println("  gdoc2doc -auth service-account -credentials ./key.json -subject user@example.com -list")
 		fmt.Println()
 		fmt.Println("Setup:")
-		fmt.Println("  Load API keys before running:")
+		fmt.Println("  Default -auth token mode loads API keys from the environment:")
 		fmt.Println("    . .\\tools\\load-key.ps1 -Service together -Target API")
 		fmt.Println("    . .\\tools\\load-key.ps1 -Service google-docs -Target JWT")
 		os.Exit(0)
@@ -53,22 +74,42 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Load Google OAuth token from environment variable
-	token, err := LoadGoogleDocsToken()
+	// Set up authentication for the selected -auth mode
+	authProvider, err := NewAuthProvider(*authMode, *credentialsPath, *subject)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Create Drive client
-	driveClient, err := NewDriveClient(token)
+	pacing := PacerConfig{MinSleep: *minSleep, MaxSleep: *maxSleep, Decay: DefaultPacerConfig.Decay}
+	driveClient, err := NewDriveClient(authProvider, pacing)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Mirror mode: walk a Drive folder tree and exit, bypassing the
+	// query/filter flow entirely.
+	if *mirrorTarget != "" {
+		fmt.Printf("Mirroring folder %s into %s...\n", *mirrorTarget, *outputDirectory)
+		if err := driveClient.MirrorFolder(*mirrorTarget, *outputDirectory, strings.Split(*preferredExtensions, ","), *parallel); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// statusOut is where human-readable progress lines go: stdout normally,
+	// but stderr in -json mode so stdout carries nothing but the final
+	// JSON payload, keeping `gdoc2doc -json "query" | jq .` parseable.
+	statusOut := os.Stdout
+	if *jsonOutput {
+		statusOut = os.Stderr
+	}
+
 	// List all Google Docs
-	fmt.Println("Fetching documents from Google Drive...")
+	fmt.Fprintln(statusOut, "Fetching documents from Google Drive...")
 	documents, err := driveClient.ListGoogleDocs()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -76,11 +117,15 @@ func main() {
 	}
 
 	if len(documents) == 0 {
-		fmt.Println("No Google Docs found in your Drive.")
+		if *jsonOutput {
+			printJSONResult(JSONResult{Query: query})
+			os.Exit(0)
+		}
+		fmt.Fprintln(statusOut, "No Google Docs found in your Drive.")
 		os.Exit(0)
 	}
 
-	fmt.Printf("Found %d documents.\n\n", len(documents))
+	fmt.Fprintf(statusOut, "Found %d documents.\n\n", len(documents))
 
 	// If list-only mode, just print documents and exit
 	if *listOnly {
@@ -102,24 +147,37 @@ func main() {
 	}
 
 	// Filter documents using Together AI
-	fmt.Printf("Filtering documents with query: %s\n", query)
-	fmt.Println("Sending to Together AI...")
+	fmt.Fprintf(statusOut, "Filtering documents with query: %s\n", query)
+	fmt.Fprintln(statusOut, "Sending to Together AI...")
 
 	filter := NewDocumentFilter(togetherKey)
-	matchingNames, err := filter.FilterDocuments(documents, query)
+	matches, err := filter.FilterDocuments(documents, query)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error filtering documents: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Find full document info for matches
-	matchingDocuments := FindMatchingDocuments(documents, matchingNames)
-
-	if len(matchingDocuments) == 0 {
+	if len(matches) == 0 {
+		if *jsonOutput {
+			printJSONResult(JSONResult{Query: query})
+			os.Exit(0)
+		}
 		fmt.Println("\nNo documents matched your query.")
 		os.Exit(0)
 	}
 
+	// -json exports every match non-interactively and prints the full
+	// result set as JSON on stdout, for scripting.
+	if *jsonOutput {
+		result := exportMatchesAsJSON(driveClient, documents, matches, *outputDirectory, format, true)
+		result.Query = query
+		printJSONResult(result)
+		os.Exit(0)
+	}
+
+	// Find full document info for matches
+	matchingDocuments := FindMatchingDocuments(documents, matches)
+
 	fmt.Printf("\nFound %d matching document(s):\n", len(matchingDocuments))
 	for index, document := range matchingDocuments {
 		fmt.Printf("  %d. %s\n", index+1, document.Name)
@@ -143,10 +201,24 @@ func main() {
 		}
 
 		if input == "all" {
-			// Export all matching documents
-			for _, document := range matchingDocuments {
-				exportDocument(driveClient, document, *outputDirectory, format)
+			// Export all matching documents concurrently, recording a
+			// manifest so a re-run can skip anything unchanged.
+			manifest, err := LoadManifest(*outputDirectory)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to load manifest, starting fresh: %v\n", err)
+				manifest = NewManifest()
 			}
+
+			items := make([]bulkExportItem, len(matchingDocuments))
+			for index, document := range matchingDocuments {
+				items[index] = bulkExportItem{Document: document, Format: format}
+			}
+
+			results := exportConcurrently(driveClient, items, *outputDirectory, *parallel, manifest)
+			if err := manifest.Save(*outputDirectory); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save manifest: %v\n", err)
+			}
+			summarizeBulkResults(results)
 		} else {
 			// Try to parse as number
 			selection, err := strconv.Atoi(input)
@@ -154,28 +226,102 @@ func main() {
 				fmt.Println("Invalid selection.")
 				os.Exit(1)
 			}
-			exportDocument(driveClient, matchingDocuments[selection-1], *outputDirectory, format)
+			exportDocument(driveClient, matchingDocuments[selection-1], *outputDirectory, format, false)
 		}
 	} else {
 		// Single match - export directly
-		exportDocument(driveClient, matchingDocuments[0], *outputDirectory, format)
+		exportDocument(driveClient, matchingDocuments[0], *outputDirectory, format, false)
 	}
 }
 
-func exportDocument(client *DriveClient, document GoogleDocument, outputDirectory string, format ExportFormat) {
+// exportDocument exports a single document, printing progress as it goes,
+// and returns the output path and byte count so callers like -json can
+// report on it. jsonOutput routes those progress lines to stderr instead
+// of stdout, so stdout stays reserved for the JSON payload in -json mode.
+func exportDocument(client *DriveClient, document GoogleDocument, outputDirectory string, format ExportFormat, jsonOutput bool) (string, int64, error) {
+	statusOut := os.Stdout
+	if jsonOutput {
+		statusOut = os.Stderr
+	}
+
 	filename := SanitizeFilename(document.Name) + format.Extension
 	outputPath := filename
 	if outputDirectory != "." && outputDirectory != "" {
 		outputPath = outputDirectory + "/" + filename
 	}
 
-	fmt.Printf("\nExporting: %s -> %s\n", document.Name, outputPath)
+	fmt.Fprintf(statusOut, "\nExporting: %s -> %s\n", document.Name, outputPath)
 
-	err := client.ExportDocument(document.ID, outputPath, format)
+	bytesWritten, err := client.ExportDocument(document.ID, outputPath, format, nil)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error exporting %s: %v\n", document.Name, err)
-		return
+		return outputPath, 0, err
+	}
+
+	fmt.Fprintf(statusOut, "Exported %d bytes to %s\n", bytesWritten, outputPath)
+	fmt.Fprintln(statusOut, "Export complete!")
+	return outputPath, bytesWritten, nil
+}
+
+// JSONResult is the machine-readable result emitted by -json: the query,
+// and every AI-reported match together with its export outcome.
+type JSONResult struct {
+	Query   string            `json:"query"`
+	Matches []JSONMatchResult `json:"matches"`
+}
+
+// JSONMatchResult is one matched document plus the outcome of exporting it.
+type JSONMatchResult struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+	OutputPath string  `json:"output_path,omitempty"`
+	Bytes      int64   `json:"bytes,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// exportMatchesAsJSON exports every AI match (there is no interactive
+// choice in -json mode) and records the outcome of each one. jsonOutput is
+// forwarded to exportDocument so its progress lines land on stderr,
+// keeping stdout reserved for the JSON payload this feeds into.
+func exportMatchesAsJSON(client *DriveClient, documents []GoogleDocument, matches []FilterMatch, outputDirectory string, format ExportFormat, jsonOutput bool) JSONResult {
+	byID := make(map[string]GoogleDocument, len(documents))
+	for _, document := range documents {
+		byID[document.ID] = document
+	}
+
+	result := JSONResult{Matches: make([]JSONMatchResult, 0, len(matches))}
+	for _, match := range matches {
+		entry := JSONMatchResult{ID: match.ID, Name: match.Name, Confidence: match.Confidence, Reason: match.Reason}
+
+		document, ok := byID[match.ID]
+		if !ok {
+			entry.Error = "document id not found in Drive listing"
+			result.Matches = append(result.Matches, entry)
+			continue
+		}
+
+		outputPath, bytesWritten, err := exportDocument(client, document, outputDirectory, format, jsonOutput)
+		entry.OutputPath = outputPath
+		if err != nil {
+			entry.Error = err.Error()
+		} else {
+			entry.Bytes = bytesWritten
+		}
+
+		result.Matches = append(result.Matches, entry)
 	}
 
-	fmt.Println("Export complete!")
+	return result
+}
+
+// printJSONResult writes result to stdout as indented JSON.
+func printJSONResult(result JSONResult) {
+	encoded, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON result: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(encoded))
 }
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// manifestFileName is the name of the manifest written to (and read back
+// from) an export's output directory.
+const manifestFileName = "manifest.json"
+
+// ManifestEntry records the outcome of exporting one document, so a later
+// run can tell whether the source has changed since and skip re-exporting
+// it - the same ID+modifiedTime+hash check rsync-style sync tools use.
+type ManifestEntry struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	ModifiedAt string `json:"modified_at"`
+	Format     string `json:"format"`
+	OutputPath string `json:"output_path"`
+	SHA256     string `json:"sha256"`
+	Bytes      int64  `json:"bytes"`
+}
+
+// Manifest is the manifest.json written to an output directory after a
+// bulk export or mirror. It's safe for concurrent use so worker-pool
+// exports can record entries as they finish.
+type Manifest struct {
+	mutex   sync.Mutex
+	Entries map[string]ManifestEntry
+}
+
+// NewManifest returns an empty manifest.
+func NewManifest() *Manifest {
+	return &Manifest{Entries: make(map[string]ManifestEntry)}
+}
+
+// LoadManifest reads manifest.json from outputDirectory. A missing file
+// isn't an error - it just means there's nothing to resume from yet.
+func LoadManifest(outputDirectory string) (*Manifest, error) {
+	manifest := NewManifest()
+
+	data, err := os.ReadFile(filepath.Join(outputDirectory, manifestFileName))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &manifest.Entries); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// Save writes the manifest to outputDirectory as indented JSON.
+func (manifest *Manifest) Save(outputDirectory string) error {
+	manifest.mutex.Lock()
+	defer manifest.mutex.Unlock()
+
+	data, err := json.MarshalIndent(manifest.Entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(outputDirectory, manifestFileName), data, 0644)
+}
+
+// Put records or replaces a document's manifest entry.
+func (manifest *Manifest) Put(entry ManifestEntry) {
+	manifest.mutex.Lock()
+	defer manifest.mutex.Unlock()
+	manifest.Entries[entry.ID] = entry
+}
+
+// UpToDate reports whether documentID at modifiedTime was already
+// exported to outputPath with a hash that still matches the file on disk,
+// meaning this export can be skipped.
+func (manifest *Manifest) UpToDate(documentID, modifiedTime, outputPath string) bool {
+	manifest.mutex.Lock()
+	entry, ok := manifest.Entries[documentID]
+	manifest.mutex.Unlock()
+
+	if !ok || entry.ModifiedAt != modifiedTime || entry.OutputPath != outputPath {
+		return false
+	}
+
+	hash, err := fileSHA256(outputPath)
+	if err != nil {
+		return false
+	}
+
+	return hash == entry.SHA256
+}
+
+// fileSHA256 returns the hex-encoded SHA-256 digest of the file at path.
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
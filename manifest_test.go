@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, directory, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(directory, name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	return path
+}
+
+func TestManifestUpToDate(t *testing.T) {
+	directory := t.TempDir()
+	outputPath := writeTempFile(t, directory, "doc.pdf", "exported contents")
+
+	hash, err := fileSHA256(outputPath)
+	if err != nil {
+		t.Fatalf("fileSHA256: %v", err)
+	}
+
+	manifest := NewManifest()
+	manifest.Put(ManifestEntry{
+		ID:         "doc-1",
+		ModifiedAt: "2026-01-01T00:00:00Z",
+		OutputPath: outputPath,
+		SHA256:     hash,
+	})
+
+	if !manifest.UpToDate("doc-1", "2026-01-01T00:00:00Z", outputPath) {
+		t.Error("expected UpToDate to report true for an unchanged export")
+	}
+
+	if manifest.UpToDate("doc-1", "2026-02-01T00:00:00Z", outputPath) {
+		t.Error("expected UpToDate to report false once modifiedTime has changed")
+	}
+
+	if manifest.UpToDate("doc-2", "2026-01-01T00:00:00Z", outputPath) {
+		t.Error("expected UpToDate to report false for a document with no entry")
+	}
+
+	if err := os.WriteFile(outputPath, []byte("edited after export"), 0644); err != nil {
+		t.Fatalf("failed to modify output file: %v", err)
+	}
+	if manifest.UpToDate("doc-1", "2026-01-01T00:00:00Z", outputPath) {
+		t.Error("expected UpToDate to report false once the on-disk file no longer matches the recorded hash")
+	}
+}
+
+func TestLoadManifestMissingFileIsNotAnError(t *testing.T) {
+	manifest, err := LoadManifest(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadManifest on an empty directory returned an error: %v", err)
+	}
+	if len(manifest.Entries) != 0 {
+		t.Errorf("expected an empty manifest, got %d entries", len(manifest.Entries))
+	}
+}
+
+func TestManifestSaveAndReload(t *testing.T) {
+	directory := t.TempDir()
+
+	manifest := NewManifest()
+	manifest.Put(ManifestEntry{ID: "doc-1", Name: "Doc One", ModifiedAt: "2026-01-01T00:00:00Z", OutputPath: "doc-1.pdf", SHA256: "abc123"})
+
+	if err := manifest.Save(directory); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reloaded, err := LoadManifest(directory)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+
+	entry, ok := reloaded.Entries["doc-1"]
+	if !ok {
+		t.Fatal("expected reloaded manifest to contain doc-1")
+	}
+	if entry.SHA256 != "abc123" {
+		t.Errorf("SHA256 = %q, want %q", entry.SHA256, "abc123")
+	}
+}
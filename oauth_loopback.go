@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/drive/v3"
+)
+
+// OAuthLoopbackAuthProvider runs an interactive OAuth flow: it spins up a
+// localhost redirect server, opens the consent URL in the user's browser,
+// exchanges the returned authorization code for a token, and persists the
+// refresh token so later runs can skip the browser step entirely.
+type OAuthLoopbackAuthProvider struct {
+	CredentialsPath string
+}
+
+func (provider OAuthLoopbackAuthProvider) TokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	keyData, err := os.ReadFile(provider.CredentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OAuth client credentials %q: %w", provider.CredentialsPath, err)
+	}
+
+	config, err := google.ConfigFromJSON(keyData, drive.DriveScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OAuth client credentials: %w", err)
+	}
+
+	tokenPath, err := oauthTokenStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	if token, err := loadStoredOAuthToken(tokenPath); err == nil {
+		return config.TokenSource(ctx, token), nil
+	}
+
+	token, err := runOAuthLoopbackFlow(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete OAuth flow: %w", err)
+	}
+
+	if err := saveOAuthToken(tokenPath, token); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to persist OAuth token to %s: %v\n", tokenPath, err)
+	}
+
+	return config.TokenSource(ctx, token), nil
+}
+
+// oauthTokenStorePath returns the path persisted OAuth tokens are read
+// from and written to: ~/.config/gdoc2doc/token.json.
+func oauthTokenStorePath() (string, error) {
+	homeDirectory, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(homeDirectory, ".config", "gdoc2doc", "token.json"), nil
+}
+
+func loadStoredOAuthToken(path string) (*oauth2.Token, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse stored token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// saveOAuthToken persists token to path with 0600 permissions, creating
+// the parent directory if needed.
+func saveOAuthToken(path string, token *oauth2.Token) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// runOAuthLoopbackFlow listens on a loopback port, opens the consent URL
+// in the user's browser, and waits for Google to redirect back with an
+// authorization code, which it then exchanges for a token.
+func runOAuthLoopbackFlow(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start local redirect server: %w", err)
+	}
+	defer listener.Close()
+
+	config.RedirectURL = fmt.Sprintf("http://127.0.0.1:%d/callback", listener.Addr().(*net.TCPAddr).Port)
+
+	state, err := randomOAuthState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate OAuth state: %w", err)
+	}
+
+	codeChannel := make(chan string, 1)
+	errChannel := make(chan error, 1)
+
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("state") != state {
+				errChannel <- fmt.Errorf("callback state did not match the request we sent")
+				http.Error(w, "invalid state parameter", http.StatusBadRequest)
+				return
+			}
+
+			code := r.URL.Query().Get("code")
+			if code == "" {
+				errChannel <- fmt.Errorf("no authorization code in callback request")
+				http.Error(w, "missing authorization code", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintln(w, "Authentication complete, you can close this tab.")
+			codeChannel <- code
+		}),
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	authURL := config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	fmt.Printf("Open this URL to authorize gdoc2doc, or it should open automatically:\n%s\n", authURL)
+	openBrowser(authURL)
+
+	select {
+	case code := <-codeChannel:
+		return config.Exchange(ctx, code)
+	case err := <-errChannel:
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// randomOAuthState returns a random value to send as the OAuth state
+// parameter and check on callback, so a request to our redirect URL from
+// somewhere other than the consent flow we just started is rejected.
+func randomOAuthState() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// openBrowser best-effort opens url in the user's default browser; a
+// failure here isn't fatal since the URL is also printed to the terminal.
+func openBrowser(url string) {
+	var command *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		command = exec.Command("open", url)
+	case "windows":
+		command = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		command = exec.Command("xdg-open", url)
+	}
+	_ = command.Start()
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// maxPacerRetries bounds how many times pacer.call attempts a single
+// operation, the first try included, before giving up and returning the
+// last error it saw.
+const maxPacerRetries = 10
+
+// PacerConfig configures the backoff a pacer uses between retries.
+type PacerConfig struct {
+	MinSleep time.Duration
+	MaxSleep time.Duration
+	Decay    float64
+}
+
+// DefaultPacerConfig mirrors rclone's Drive backend: start with a short
+// delay, back off aggressively on repeated rate limiting, cap at a couple
+// of seconds so a flaky run doesn't stall forever.
+var DefaultPacerConfig = PacerConfig{
+	MinSleep: 10 * time.Millisecond,
+	MaxSleep: 2 * time.Second,
+	Decay:    2,
+}
+
+// pacer rate-limits and retries Drive API calls with exponential backoff
+// plus jitter, so a burst of rateLimitExceeded/429/5xx responses slows the
+// client down instead of failing the whole operation outright. A single
+// pacer is shared across every call a DriveClient makes, including
+// concurrent calls from exportConcurrently's worker pool, so access to
+// sleep is serialized by mutex.
+type pacer struct {
+	mutex    sync.Mutex
+	minSleep time.Duration
+	maxSleep time.Duration
+	decay    float64
+	sleep    time.Duration
+}
+
+// newPacer creates a pacer starting at config.MinSleep.
+func newPacer(config PacerConfig) *pacer {
+	return &pacer{
+		minSleep: config.MinSleep,
+		maxSleep: config.MaxSleep,
+		decay:    config.Decay,
+		sleep:    config.MinSleep,
+	}
+}
+
+// call runs fn, retrying with exponential backoff while it keeps returning
+// a retryable error, up to maxPacerRetries attempts.
+func (p *pacer) call(fn func() error) error {
+	var lastErr error
+
+	for attempt := 0; attempt < maxPacerRetries; attempt++ {
+		err := fn()
+		if err == nil {
+			p.mutex.Lock()
+			p.sleep = p.minSleep
+			p.mutex.Unlock()
+			return nil
+		}
+
+		if !isRetryableDriveError(err) {
+			return err
+		}
+
+		lastErr = err
+		time.Sleep(p.nextDelay())
+	}
+
+	return lastErr
+}
+
+// nextDelay returns the delay to wait before the next retry and grows the
+// pacer's sleep duration (capped at maxSleep) for the attempt after that.
+// Jitter keeps concurrent callers from retrying in lockstep.
+func (p *pacer) nextDelay() time.Duration {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delay := p.sleep/2 + time.Duration(rand.Int63n(int64(p.sleep)+1))/2
+
+	next := time.Duration(float64(p.sleep) * p.decay)
+	if next > p.maxSleep {
+		next = p.maxSleep
+	}
+	p.sleep = next
+
+	return delay
+}
+
+// isRetryableDriveError reports whether err is a transient Drive API error
+// worth retrying: rate limiting (403 rateLimitExceeded/userRateLimitExceeded,
+// or 429) and server-side failures (5xx).
+func isRetryableDriveError(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	if apiErr.Code == http.StatusTooManyRequests {
+		return true
+	}
+
+	if apiErr.Code == http.StatusForbidden {
+		for _, reason := range apiErr.Errors {
+			if reason.Reason == "rateLimitExceeded" || reason.Reason == "userRateLimitExceeded" {
+				return true
+			}
+		}
+		return false
+	}
+
+	return apiErr.Code >= 500 && apiErr.Code < 600
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestPacerNextDelayGrowsAndCaps(t *testing.T) {
+	p := newPacer(PacerConfig{MinSleep: 10 * time.Millisecond, MaxSleep: 100 * time.Millisecond, Decay: 2})
+
+	for i := 0; i < 10; i++ {
+		delay := p.nextDelay()
+		if delay < 0 {
+			t.Fatalf("nextDelay returned a negative delay: %v", delay)
+		}
+		if p.sleep > p.maxSleep {
+			t.Fatalf("sleep grew past maxSleep: %v > %v", p.sleep, p.maxSleep)
+		}
+	}
+}
+
+func TestPacerCallResetsSleepOnSuccess(t *testing.T) {
+	p := newPacer(PacerConfig{MinSleep: 10 * time.Millisecond, MaxSleep: 100 * time.Millisecond, Decay: 2})
+	p.sleep = p.maxSleep
+
+	if err := p.call(func() error { return nil }); err != nil {
+		t.Fatalf("call returned an error for a successful fn: %v", err)
+	}
+
+	if p.sleep != p.minSleep {
+		t.Errorf("sleep = %v after a successful call, want minSleep %v", p.sleep, p.minSleep)
+	}
+}
+
+func TestPacerCallStopsOnNonRetryableError(t *testing.T) {
+	p := newPacer(DefaultPacerConfig)
+
+	wantErr := errors.New("not retryable")
+	calls := 0
+	err := p.call(func() error {
+		calls++
+		return wantErr
+	})
+
+	if err != wantErr {
+		t.Errorf("call returned %v, want %v", err, wantErr)
+	}
+	if calls != 1 {
+		t.Errorf("fn was called %d times, want 1 for a non-retryable error", calls)
+	}
+}
+
+func TestPacerCallRetriesUpToMax(t *testing.T) {
+	p := newPacer(PacerConfig{MinSleep: time.Microsecond, MaxSleep: time.Microsecond, Decay: 2})
+
+	calls := 0
+	err := p.call(func() error {
+		calls++
+		return &googleapi.Error{Code: http.StatusTooManyRequests}
+	})
+
+	if err == nil {
+		t.Fatal("expected call to return the last error after exhausting retries")
+	}
+	if calls != maxPacerRetries {
+		t.Errorf("fn was called %d times, want %d", calls, maxPacerRetries)
+	}
+}
+
+func TestIsRetryableDriveError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"plain error", errors.New("boom"), false},
+		{"429", &googleapi.Error{Code: http.StatusTooManyRequests}, true},
+		{"500", &googleapi.Error{Code: http.StatusInternalServerError}, true},
+		{"403 rate limit", &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}}, true},
+		{"403 user rate limit", &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "userRateLimitExceeded"}}}, true},
+		{"403 other reason", &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "insufficientPermissions"}}}, false},
+		{"404", &googleapi.Error{Code: http.StatusNotFound}, false},
+		{"416", &googleapi.Error{Code: http.StatusRequestedRangeNotSatisfiable}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isRetryableDriveError(test.err); got != test.want {
+				t.Errorf("isRetryableDriveError(%v) = %v, want %v", test.err, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// barWidth is how many characters wide each rendered progress bar is.
+const barWidth = 30
+
+// progressBoard renders one progress line per in-flight export plus a
+// trailing aggregate line, redrawing them in place with ANSI cursor moves
+// the way multi-file download tools (apt, docker pull) do.
+type progressBoard struct {
+	mutex sync.Mutex
+	lines []string
+	total int
+	done  int
+}
+
+// newProgressBoard reserves total lines plus one aggregate line.
+func newProgressBoard(total int) *progressBoard {
+	board := &progressBoard{total: total, lines: make([]string, total)}
+	fmt.Print(strings.Repeat("\n", total+1))
+	return board
+}
+
+// update redraws slot's progress line and the aggregate line beneath it.
+func (board *progressBoard) update(slot int, label string, written, total int64) {
+	board.mutex.Lock()
+	defer board.mutex.Unlock()
+
+	board.lines[slot] = renderBar(label, written, total)
+	board.redrawLocked()
+}
+
+// finish marks slot's export as complete, labeling it with status.
+func (board *progressBoard) finish(slot int, label, status string) {
+	board.mutex.Lock()
+	defer board.mutex.Unlock()
+
+	board.lines[slot] = fmt.Sprintf("%-28s %s", label, status)
+	board.done++
+	board.redrawLocked()
+}
+
+func (board *progressBoard) redrawLocked() {
+	fmt.Printf("\033[%dA", len(board.lines)+1)
+	for _, line := range board.lines {
+		fmt.Printf("\r\033[K%s\n", line)
+	}
+	fmt.Printf("\r\033[K%d/%d complete\n", board.done, board.total)
+}
+
+// renderBar draws a single progress bar. With an unknown total it falls
+// back to a running byte count instead of a percentage.
+func renderBar(label string, written, total int64) string {
+	if total <= 0 {
+		return fmt.Sprintf("%-28s %d bytes", label, written)
+	}
+
+	filled := int(float64(barWidth) * float64(written) / float64(total))
+	if filled > barWidth {
+		filled = barWidth
+	}
+
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	percent := 100 * float64(written) / float64(total)
+	return fmt.Sprintf("%-28s [%s] %5.1f%%", label, bar, percent)
+}
@@ -14,8 +14,9 @@ const TogetherModel = "ServiceNow-AI/Apriel-1.6-15b-Thinker"
 
 // TogetherRequest represents a chat completion request
 type TogetherRequest struct {
-	Model    string           `json:"model"`
-	Messages []TogetherMessage `json:"messages"`
+	Model          string            `json:"model"`
+	Messages       []TogetherMessage `json:"messages"`
+	ResponseFormat *ResponseFormat   `json:"response_format,omitempty"`
 }
 
 // TogetherMessage represents a single message in the conversation
@@ -24,6 +25,12 @@ type TogetherMessage struct {
 	Content string `json:"content"`
 }
 
+// ResponseFormat asks Together AI to constrain its output to a JSON object
+// instead of free text, per the chat completions API's response_format field.
+type ResponseFormat struct {
+	Type string `json:"type"`
+}
+
 // TogetherResponse represents the API response
 type TogetherResponse struct {
 	Choices []struct {
@@ -36,6 +43,20 @@ type TogetherResponse struct {
 	} `json:"error"`
 }
 
+// FilterMatch is one document the AI reported as matching the query.
+type FilterMatch struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+}
+
+// filterMatchesResponse is the strict JSON schema the AI is asked to
+// return: {"matches":[{"id":...,"name":...,"confidence":...,"reason":...}]}.
+type filterMatchesResponse struct {
+	Matches []FilterMatch `json:"matches"`
+}
+
 // DocumentFilter uses Together AI to filter documents based on natural language
 type DocumentFilter struct {
 	apiKey string
@@ -46,37 +67,41 @@ func NewDocumentFilter(apiKey string) *DocumentFilter {
 	return &DocumentFilter{apiKey: apiKey}
 }
 
-// FilterDocuments sends document list to Together AI and returns matching document names
-func (filter *DocumentFilter) FilterDocuments(documents []GoogleDocument, query string) ([]string, error) {
+// FilterDocuments sends the document list to Together AI and returns the
+// documents it reports as matching the query, parsed from a JSON object
+// response rather than free-text lines.
+func (filter *DocumentFilter) FilterDocuments(documents []GoogleDocument, query string) ([]FilterMatch, error) {
 	// Build the document list for the prompt
 	var documentList strings.Builder
-	for index, document := range documents {
-		documentList.WriteString(fmt.Sprintf("%d. %s", index+1, document.Name))
+	for _, document := range documents {
+		documentList.WriteString(fmt.Sprintf("id: %s, name: %s", document.ID, document.Name))
 		if document.Description != "" {
-			documentList.WriteString(fmt.Sprintf(" - %s", document.Description))
+			documentList.WriteString(fmt.Sprintf(", description: %s", document.Description))
 		}
 		documentList.WriteString("\n")
 	}
 
-	prompt := fmt.Sprintf(`You are a document filter assistant. Given a list of document names and a search query, return ONLY the names of documents that match the query.
+	prompt := fmt.Sprintf(`You are a document filter assistant. Given a list of documents and a search query, return the documents that match the query.
 
-Which documents from this list:
+Documents:
 %s
-Match the prompt: %s
+Query: %s
 
-Rules:
-1. Return ONLY the document names that match, one per line
-2. Use EXACT document names from the list
-3. If no documents match, return "NONE"
-4. Do not explain or add any other text
+Respond with ONLY a JSON object of this exact shape, and nothing else:
+{"matches":[{"id":"<document id>","name":"<document name>","confidence":0.0-1.0,"reason":"<short reason>"}]}
 
-Matching documents:`, documentList.String(), query)
+Rules:
+1. Use the exact "id" value from the document list above, never a name or index.
+2. confidence is your estimate of match quality, from 0.0 (weak) to 1.0 (certain).
+3. If no documents match, respond with {"matches":[]}.
+4. Do not add any text outside the JSON object.`, documentList.String(), query)
 
 	request := TogetherRequest{
 		Model: TogetherModel,
 		Messages: []TogetherMessage{
 			{Role: "user", Content: prompt},
 		},
+		ResponseFormat: &ResponseFormat{Type: "json_object"},
 	}
 
 	requestBody, err := json.Marshal(request)
@@ -117,52 +142,33 @@ Matching documents:`, documentList.String(), query)
 		return nil, fmt.Errorf("no response from AI model")
 	}
 
-	// Parse the response to extract matching document names
 	content := strings.TrimSpace(response.Choices[0].Message.Content)
-
-	if content == "NONE" || content == "" {
-		return []string{}, nil
+	if content == "" {
+		return nil, nil
 	}
 
-	// Split by newlines and clean up
-	lines := strings.Split(content, "\n")
-	var matchingNames []string
-
-	for _, line := range lines {
-		cleaned := strings.TrimSpace(line)
-		// Remove any bullet points or numbering
-		cleaned = strings.TrimPrefix(cleaned, "- ")
-		cleaned = strings.TrimPrefix(cleaned, "* ")
-		// Remove numbered prefixes like "1. "
-		if len(cleaned) > 3 && cleaned[1] == '.' && cleaned[2] == ' ' {
-			cleaned = cleaned[3:]
-		}
-		if len(cleaned) > 4 && cleaned[2] == '.' && cleaned[3] == ' ' {
-			cleaned = cleaned[4:]
-		}
-		cleaned = strings.TrimSpace(cleaned)
-
-		if cleaned != "" && cleaned != "NONE" {
-			matchingNames = append(matchingNames, cleaned)
-		}
+	var parsed filterMatchesResponse
+	if err := json.Unmarshal([]byte(content), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse AI match list: %w", err)
 	}
 
-	return matchingNames, nil
+	return parsed.Matches, nil
 }
 
-// FindMatchingDocuments returns full document info for matching names
-func FindMatchingDocuments(documents []GoogleDocument, matchingNames []string) []GoogleDocument {
-	var matches []GoogleDocument
-
+// FindMatchingDocuments returns full document info for the AI's matches,
+// looked up by the stable document ID rather than by fuzzy name matching.
+func FindMatchingDocuments(documents []GoogleDocument, matches []FilterMatch) []GoogleDocument {
+	byID := make(map[string]GoogleDocument, len(documents))
 	for _, document := range documents {
-		for _, name := range matchingNames {
-			// Flexible matching: exact match or contains
-			if strings.EqualFold(document.Name, name) || strings.Contains(strings.ToLower(document.Name), strings.ToLower(name)) {
-				matches = append(matches, document)
-				break
-			}
+		byID[document.ID] = document
+	}
+
+	var matched []GoogleDocument
+	for _, match := range matches {
+		if document, ok := byID[match.ID]; ok {
+			matched = append(matched, document)
 		}
 	}
 
-	return matches
+	return matched
 }